@@ -1,13 +1,18 @@
 package x509
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
 	"log/slog"
 	"math/big"
 	"net"
+	"net/url"
 	"os"
 	"time"
 )
@@ -19,73 +24,159 @@ type Certificate struct {
 	derBytes []byte
 }
 
+// CertTemplate describes the fields needed to build a certificate, whether
+// self-signed or signed by a CA. Fields left at their zero value fall back
+// to sensible defaults: KeyUsage defaults to digital signature, and
+// ExtKeyUsage defaults to server and client authentication. Whether KeyUsage
+// is set or not, key encipherment is added for RSA keys and certificate
+// signing is added when IsCA is true.
+type CertTemplate struct {
+	CommonName     string             // Subject Common Name (e.g., "example.com")
+	Organization   []string           // Subject Organization (e.g., ["My Company"])
+	DNSNames       []string           // Subject Alternative Names (DNS)
+	IPAddresses    []net.IP           // Subject Alternative Names (IP)
+	EmailAddresses []string           // Subject Alternative Names (email)
+	URIs           []*url.URL         // Subject Alternative Names (URI)
+	NotBefore      time.Time          // Start of the certificate's validity period; defaults to now
+	ValidFor       time.Duration      // Duration for which the certificate is valid
+	IsCA           bool               // Whether this certificate is a Certificate Authority
+	KeyUsage       x509.KeyUsage      // Key usage bits; defaults to KeyUsageDigitalSignature (see above for bits always added)
+	ExtKeyUsage    []x509.ExtKeyUsage // Extended key usages; defaults to server+client auth
+
+	// MaxPathLen constrains how many intermediate CA certificates may
+	// follow this one in a chain. It is only honored when IsCA is true.
+	// Set MaxPathLenZero to explicitly encode a path length of zero, since
+	// MaxPathLen's own zero value is otherwise treated as "unset" for
+	// compatibility with older certificates (matching crypto/x509's own
+	// MaxPathLen/MaxPathLenZero convention).
+	MaxPathLen     int
+	MaxPathLenZero bool
+}
+
 // NewCertificate creates a new self-signed X.509 certificate.
-func NewCertificate(
-	privKey *PrivateKey, // The private key to sign the certificate
-	commonName string, // Subject Common Name (e.g., "example.com")
-	organization []string, // Subject Organization (e.g., ["My Company"])
-	dnsNames []string, // Subject Alternative Names (DNS)
-	ipAddresses []net.IP, // Subject Alternative Names (IP)
-	validFor time.Duration, // Duration for which the certificate is valid
-	isCA bool, // Whether this certificate is a Certificate Authority
-) (*Certificate, error) {
+func NewCertificate(privKey *PrivateKey, template CertTemplate) (*Certificate, error) {
+	pubKey := privKey.Key().Public()
 
-	// Generate a random serial number
-	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
-	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	certTemplate, err := buildTemplate(pubKey, template)
 	if err != nil {
-		slog.Error("Failed to generate serial number", "error", err)
-		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+		return nil, err
 	}
 
-	// Get the current time
-	now := time.Now()
+	// Create the certificate (self-signed: template is its own parent)
+	derBytes, err := x509.CreateCertificate(rand.Reader, certTemplate, certTemplate, pubKey, privKey.Key())
+	if err != nil {
+		slog.Error("Failed to create certificate", "error", err)
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
 
-	// Create the certificate template
-	template := x509.Certificate{
-		SerialNumber: serialNumber,
-		Subject: pkix.Name{
-			CommonName:   commonName,
-			Organization: organization,
-		},
-		NotBefore: now,
-		NotAfter:  now.Add(validFor),
+	cert, err := x509.ParseCertificate(derBytes)
+	if err != nil {
+		slog.Error("Failed to parse created certificate", "error", err)
+		return nil, fmt.Errorf("failed to parse created certificate: %w", err)
+	}
 
-		KeyUsage:              x509.KeyUsageDigitalSignature,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}, // Common usages
-		BasicConstraintsValid: true,
+	slog.Info("Successfully created new certificate", "subject", template.CommonName, "serial", certTemplate.SerialNumber)
+	return &Certificate{cert: cert, derBytes: derBytes}, nil
+}
 
-		DNSNames:    dnsNames,
-		IPAddresses: ipAddresses,
-	}
+// NewSignedCertificate creates a new X.509 certificate for subjectKey, signed
+// by caKey on behalf of caCert. caCert must be a CA certificate.
+func NewSignedCertificate(subjectKey *PrivateKey, caCert *Certificate, caKey *PrivateKey, template CertTemplate) (*Certificate, error) {
+	return signCertificate(subjectKey.Key().Public(), caCert, caKey, template)
+}
 
-	// If it's a CA, set appropriate fields
-	if isCA {
-		template.IsCA = true
-		template.KeyUsage |= x509.KeyUsageCertSign
+// signCertificate issues a certificate for pubKey, signed by caKey on behalf
+// of caCert. It underlies both NewSignedCertificate, where the subject's
+// public key comes from a freshly generated PrivateKey, and
+// SignCertificateRequest, where it comes from an already-received CSR.
+func signCertificate(pubKey crypto.PublicKey, caCert *Certificate, caKey *PrivateKey, template CertTemplate) (*Certificate, error) {
+	if !caCert.Cert().IsCA {
+		return nil, fmt.Errorf("ca certificate %q is not a CA", caCert.Cert().Subject.CommonName)
 	}
 
-	// Get the public key from the private key
-	pubKey := &privKey.Key().PublicKey
+	certTemplate, err := buildTemplate(pubKey, template)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create the certificate (self-signed)
-	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, pubKey, privKey.Key())
+	derBytes, err := x509.CreateCertificate(rand.Reader, certTemplate, caCert.Cert(), pubKey, caKey.Key())
 	if err != nil {
-		slog.Error("Failed to create certificate", "error", err)
-		return nil, fmt.Errorf("failed to create certificate: %w", err)
+		slog.Error("Failed to create signed certificate", "error", err)
+		return nil, fmt.Errorf("failed to create signed certificate: %w", err)
 	}
 
-	// Parse the created certificate to get the *x509.Certificate object
 	cert, err := x509.ParseCertificate(derBytes)
 	if err != nil {
 		slog.Error("Failed to parse created certificate", "error", err)
 		return nil, fmt.Errorf("failed to parse created certificate: %w", err)
 	}
 
-	slog.Info("Successfully created new certificate", "subject", commonName, "serial", serialNumber)
+	slog.Info("Successfully created signed certificate", "subject", template.CommonName, "issuer", caCert.Cert().Subject.CommonName, "serial", certTemplate.SerialNumber)
 	return &Certificate{cert: cert, derBytes: derBytes}, nil
 }
 
+// buildTemplate turns a CertTemplate into an *x509.Certificate ready to be
+// passed to x509.CreateCertificate, filling in a random serial number and
+// the key usage defaults shared by self-signed and CA-signed issuance.
+func buildTemplate(pubKey crypto.PublicKey, template CertTemplate) (*x509.Certificate, error) {
+	// Generate a random serial number
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		slog.Error("Failed to generate serial number", "error", err)
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	notBefore := template.NotBefore
+	if notBefore.IsZero() {
+		notBefore = time.Now()
+	}
+
+	keyUsage := template.KeyUsage
+	if keyUsage == 0 {
+		keyUsage = x509.KeyUsageDigitalSignature
+	}
+	// RSA keys additionally support key encipherment (e.g. for TLS key exchange).
+	if _, ok := pubKey.(*rsa.PublicKey); ok {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	extKeyUsage := template.ExtKeyUsage
+	if extKeyUsage == nil {
+		extKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+
+	cert := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName:   template.CommonName,
+			Organization: template.Organization,
+		},
+		NotBefore: notBefore,
+		NotAfter:  notBefore.Add(template.ValidFor),
+
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           extKeyUsage,
+		BasicConstraintsValid: true,
+
+		DNSNames:       template.DNSNames,
+		IPAddresses:    template.IPAddresses,
+		EmailAddresses: template.EmailAddresses,
+		URIs:           template.URIs,
+	}
+
+	if template.IsCA {
+		cert.IsCA = true
+		cert.KeyUsage |= x509.KeyUsageCertSign
+		if template.MaxPathLen > 0 || template.MaxPathLenZero {
+			cert.MaxPathLen = template.MaxPathLen
+			cert.MaxPathLenZero = template.MaxPathLenZero
+		}
+	}
+
+	return cert, nil
+}
+
 // Cert returns the underlying *x509.Certificate.
 func (c *Certificate) Cert() *x509.Certificate {
 	return c.cert
@@ -109,3 +200,68 @@ func (c *Certificate) SaveToFile(filePath string) error {
 	slog.Info("Saved certificate in DER format", "path", filePath)
 	return nil
 }
+
+// SaveToPEMFile saves the certificate to the specified file path in PEM format.
+func (c *Certificate) SaveToPEMFile(filePath string) error {
+	if c.derBytes == nil {
+		return fmt.Errorf("certificate DER bytes are nil, cannot save")
+	}
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: c.derBytes}
+	if err := os.WriteFile(filePath, pem.EncodeToMemory(block), 0644); err != nil {
+		slog.Error("Failed to write PEM data to certificate file", "path", filePath, "error", err)
+		return fmt.Errorf("failed to write PEM data to certificate file %s: %w", filePath, err)
+	}
+	slog.Info("Saved certificate in PEM format", "path", filePath)
+	return nil
+}
+
+// LoadCertificateFromFile loads an existing certificate from a file,
+// transparently accepting either PEM or DER encoding.
+func LoadCertificateFromFile(filePath string) (*Certificate, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		slog.Error("Failed to read certificate file", "path", filePath, "error", err)
+		return nil, fmt.Errorf("failed to read certificate file %s: %w", filePath, err)
+	}
+
+	derBytes := data
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("failed to decode PEM block from certificate file %s", filePath)
+		}
+		derBytes = block.Bytes
+	}
+
+	cert, err := x509.ParseCertificate(derBytes)
+	if err != nil {
+		slog.Error("Failed to parse certificate", "path", filePath, "error", err)
+		return nil, fmt.Errorf("failed to parse certificate from %s: %w", filePath, err)
+	}
+
+	slog.Info("Loaded certificate", "path", filePath)
+	return &Certificate{cert: cert, derBytes: derBytes}, nil
+}
+
+// LoadCertificateFromPEMFile loads an existing certificate from a PEM-encoded file.
+func LoadCertificateFromPEMFile(filePath string) (*Certificate, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		slog.Error("Failed to read certificate file", "path", filePath, "error", err)
+		return nil, fmt.Errorf("failed to read certificate file %s: %w", filePath, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block from certificate file %s", filePath)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		slog.Error("Failed to parse PEM-encoded certificate", "path", filePath, "error", err)
+		return nil, fmt.Errorf("failed to parse PEM-encoded certificate from %s: %w", filePath, err)
+	}
+
+	slog.Info("Loaded certificate in PEM format", "path", filePath)
+	return &Certificate{cert: cert, derBytes: block.Bytes}, nil
+}
@@ -0,0 +1,152 @@
+package x509
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+)
+
+// CertificateRequest represents a PKCS#10 Certificate Signing Request.
+type CertificateRequest struct {
+	csr *x509.CertificateRequest
+	// Store the DER bytes as well, useful for saving
+	derBytes []byte
+}
+
+// NewCertificateRequest creates a new CSR for key, signed by key itself as
+// proof of possession.
+func NewCertificateRequest(key *PrivateKey, subject pkix.Name, dnsNames []string, ipAddresses []net.IP) (*CertificateRequest, error) {
+	template := x509.CertificateRequest{
+		Subject:     subject,
+		DNSNames:    dnsNames,
+		IPAddresses: ipAddresses,
+	}
+
+	derBytes, err := x509.CreateCertificateRequest(rand.Reader, &template, key.Key())
+	if err != nil {
+		slog.Error("Failed to create certificate request", "error", err)
+		return nil, fmt.Errorf("failed to create certificate request: %w", err)
+	}
+
+	csr, err := x509.ParseCertificateRequest(derBytes)
+	if err != nil {
+		slog.Error("Failed to parse created certificate request", "error", err)
+		return nil, fmt.Errorf("failed to parse created certificate request: %w", err)
+	}
+
+	slog.Info("Successfully created new certificate request", "subject", subject.CommonName)
+	return &CertificateRequest{csr: csr, derBytes: derBytes}, nil
+}
+
+// CSR returns the underlying *x509.CertificateRequest.
+func (c *CertificateRequest) CSR() *x509.CertificateRequest {
+	return c.csr
+}
+
+// DERBytes returns the DER-encoded bytes of the certificate request.
+func (c *CertificateRequest) DERBytes() []byte {
+	return c.derBytes
+}
+
+// SaveToFile saves the certificate request to the specified file path in DER format.
+func (c *CertificateRequest) SaveToFile(filePath string) error {
+	if err := os.WriteFile(filePath, c.derBytes, 0644); err != nil {
+		slog.Error("Failed to write DER data to certificate request file", "path", filePath, "error", err)
+		return fmt.Errorf("failed to write DER data to certificate request file %s: %w", filePath, err)
+	}
+	slog.Info("Saved certificate request in DER format", "path", filePath)
+	return nil
+}
+
+// SaveToPEMFile saves the certificate request to the specified file path in PEM format.
+func (c *CertificateRequest) SaveToPEMFile(filePath string) error {
+	block := &pem.Block{Type: "CERTIFICATE REQUEST", Bytes: c.derBytes}
+	if err := os.WriteFile(filePath, pem.EncodeToMemory(block), 0644); err != nil {
+		slog.Error("Failed to write PEM data to certificate request file", "path", filePath, "error", err)
+		return fmt.Errorf("failed to write PEM data to certificate request file %s: %w", filePath, err)
+	}
+	slog.Info("Saved certificate request in PEM format", "path", filePath)
+	return nil
+}
+
+// LoadCertificateRequestFromFile loads an existing certificate request from a
+// file, transparently accepting either PEM or DER encoding.
+func LoadCertificateRequestFromFile(filePath string) (*CertificateRequest, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		slog.Error("Failed to read certificate request file", "path", filePath, "error", err)
+		return nil, fmt.Errorf("failed to read certificate request file %s: %w", filePath, err)
+	}
+
+	derBytes := data
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("failed to decode PEM block from certificate request file %s", filePath)
+		}
+		derBytes = block.Bytes
+	}
+
+	csr, err := x509.ParseCertificateRequest(derBytes)
+	if err != nil {
+		slog.Error("Failed to parse certificate request", "path", filePath, "error", err)
+		return nil, fmt.Errorf("failed to parse certificate request from %s: %w", filePath, err)
+	}
+
+	slog.Info("Loaded certificate request", "path", filePath)
+	return &CertificateRequest{csr: csr, derBytes: derBytes}, nil
+}
+
+// LoadCertificateRequestFromPEMFile loads an existing certificate request
+// from a PEM-encoded file.
+func LoadCertificateRequestFromPEMFile(filePath string) (*CertificateRequest, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		slog.Error("Failed to read certificate request file", "path", filePath, "error", err)
+		return nil, fmt.Errorf("failed to read certificate request file %s: %w", filePath, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block from certificate request file %s", filePath)
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		slog.Error("Failed to parse PEM-encoded certificate request", "path", filePath, "error", err)
+		return nil, fmt.Errorf("failed to parse PEM-encoded certificate request from %s: %w", filePath, err)
+	}
+
+	slog.Info("Loaded certificate request in PEM format", "path", filePath)
+	return &CertificateRequest{csr: csr, derBytes: block.Bytes}, nil
+}
+
+// SignCertificateRequest verifies csr's signature and issues a certificate
+// for it, signed by caKey on behalf of caCert. The subject's Common Name and
+// Organization, the SANs, and the public key are copied from the CSR; other
+// subject attributes and any requested extensions are not carried over.
+func SignCertificateRequest(csr *CertificateRequest, caCert *Certificate, caKey *PrivateKey, validFor time.Duration) (*Certificate, error) {
+	if err := csr.csr.CheckSignature(); err != nil {
+		slog.Error("Certificate request signature verification failed", "error", err)
+		return nil, fmt.Errorf("certificate request signature verification failed: %w", err)
+	}
+
+	template := CertTemplate{
+		CommonName:     csr.csr.Subject.CommonName,
+		Organization:   csr.csr.Subject.Organization,
+		DNSNames:       csr.csr.DNSNames,
+		IPAddresses:    csr.csr.IPAddresses,
+		EmailAddresses: csr.csr.EmailAddresses,
+		URIs:           csr.csr.URIs,
+		ValidFor:       validFor,
+	}
+
+	return signCertificate(csr.csr.PublicKey, caCert, caKey, template)
+}
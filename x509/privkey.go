@@ -1,78 +1,207 @@
 package x509
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"log/slog"
 	"os"
 )
 
-// PrivateKey represents an ECDSA private key.
+// PrivateKey represents a private key usable for certificate signing.
+// It wraps a crypto.Signer so RSA, ECDSA, and Ed25519 keys can all be
+// handled uniformly.
 type PrivateKey struct {
-	key *ecdsa.PrivateKey
+	signer crypto.Signer
 }
 
-// NewPrivateKey generates a new ECDSA private key.
+// NewPrivateKey generates a new ECDSA P-256 private key. It is kept for
+// backwards compatibility; prefer NewECDSAPrivateKey, NewRSAPrivateKey, or
+// NewEd25519PrivateKey when the desired algorithm matters.
 func NewPrivateKey() (*PrivateKey, error) {
-	// Generate a new ECDSA private key
-	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	return NewECDSAPrivateKey(elliptic.P256())
+}
+
+// NewECDSAPrivateKey generates a new ECDSA private key on the given curve.
+func NewECDSAPrivateKey(curve elliptic.Curve) (*PrivateKey, error) {
+	priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		slog.Error("Failed to generate private key", "error", err)
+		return nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+	slog.Info("Generated new private key", "algorithm", "ecdsa", "curve", curve.Params().Name)
+	return &PrivateKey{signer: priv}, nil
+}
+
+// NewRSAPrivateKey generates a new RSA private key with the given modulus size.
+func NewRSAPrivateKey(bits int) (*PrivateKey, error) {
+	priv, err := rsa.GenerateKey(rand.Reader, bits)
 	if err != nil {
-		// Use slog for error logging
 		slog.Error("Failed to generate private key", "error", err)
 		return nil, fmt.Errorf("failed to generate private key: %w", err)
 	}
-	// Use slog for info logging
-	slog.Info("Generated new private key")
-	return &PrivateKey{key: priv}, nil
+	slog.Info("Generated new private key", "algorithm", "rsa", "bits", bits)
+	return &PrivateKey{signer: priv}, nil
 }
 
-// Key returns the underlying *ecdsa.PrivateKey.
-func (p *PrivateKey) Key() *ecdsa.PrivateKey {
-	return p.key
+// NewEd25519PrivateKey generates a new Ed25519 private key.
+func NewEd25519PrivateKey() (*PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		slog.Error("Failed to generate private key", "error", err)
+		return nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+	slog.Info("Generated new private key", "algorithm", "ed25519")
+	return &PrivateKey{signer: priv}, nil
 }
 
-// SaveToFile saves the private key to the specified file path in DER format.
+// Key returns the underlying crypto.Signer.
+func (p *PrivateKey) Key() crypto.Signer {
+	return p.signer
+}
+
+// SaveToFile saves the private key to the specified file path in PKCS#8 DER format.
 func (p *PrivateKey) SaveToFile(filePath string) error {
-	// Marshal the private key to DER format
-	der, err := x509.MarshalECPrivateKey(p.key)
+	// Marshal the private key to PKCS#8 DER format, which supports RSA,
+	// ECDSA, and Ed25519 keys alike.
+	der, err := x509.MarshalPKCS8PrivateKey(p.signer)
 	if err != nil {
-		// Use slog for error logging
 		slog.Error("Failed to marshal private key to DER", "error", err)
 		return fmt.Errorf("failed to marshal private key to DER: %w", err)
 	}
 
 	// Save the DER-encoded private key to a file
 	if err := os.WriteFile(filePath, der, 0600); err != nil { // Use 0600 for private key permissions
-		// Use slog for error logging
 		slog.Error("Failed to write DER data to private key file", "path", filePath, "error", err)
 		return fmt.Errorf("failed to write DER data to private key file %s: %w", filePath, err)
 	}
-	// Use slog for info logging
 	slog.Info("Saved private key in DER format", "path", filePath)
 	return nil
 }
 
-// LoadPrivateKeyFromFile loads an existing private key from a DER-encoded file.
+// SaveToPEMFile saves the private key to the specified file path in PEM
+// format. The block type matches the key's native encoding (EC PRIVATE KEY
+// for ECDSA, RSA PRIVATE KEY for RSA, PRIVATE KEY/PKCS#8 for Ed25519), the
+// same convention used by Go's stock generate_cert.go.
+func (p *PrivateKey) SaveToPEMFile(filePath string) error {
+	block, err := pemBlockForPrivateKey(p.signer)
+	if err != nil {
+		slog.Error("Failed to marshal private key to PEM", "error", err)
+		return fmt.Errorf("failed to marshal private key to PEM: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, pem.EncodeToMemory(block), 0600); err != nil {
+		slog.Error("Failed to write PEM data to private key file", "path", filePath, "error", err)
+		return fmt.Errorf("failed to write PEM data to private key file %s: %w", filePath, err)
+	}
+	slog.Info("Saved private key in PEM format", "path", filePath)
+	return nil
+}
+
+// pemBlockForPrivateKey encodes signer using the traditional format for its
+// algorithm, falling back to PKCS#8 for algorithms without one (Ed25519).
+func pemBlockForPrivateKey(signer crypto.Signer) (*pem.Block, error) {
+	switch key := signer.(type) {
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}, nil
+	case *rsa.PrivateKey:
+		return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}, nil
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(signer)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: der}, nil
+	}
+}
+
+// LoadPrivateKeyFromFile loads an existing private key from a file,
+// transparently accepting either PEM or PKCS#8 DER encoding.
 func LoadPrivateKeyFromFile(filePath string) (*PrivateKey, error) {
-	// Read the DER-encoded private key file
-	derData, err := os.ReadFile(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
-		// Use slog for error logging
 		slog.Error("Failed to read private key file", "path", filePath, "error", err)
 		return nil, fmt.Errorf("failed to read private key file %s: %w", filePath, err)
 	}
 
-	// Parse the DER-encoded private key
-	privKey, err := x509.ParseECPrivateKey(derData)
+	var signer crypto.Signer
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("failed to decode PEM block from private key file %s", filePath)
+		}
+		signer, err = parsePrivateKeyPEMBlock(block)
+	} else {
+		signer, err = parsePKCS8Signer(data)
+	}
+	if err != nil {
+		slog.Error("Failed to parse private key", "path", filePath, "error", err)
+		return nil, fmt.Errorf("failed to parse private key from %s: %w", filePath, err)
+	}
+
+	slog.Info("Loaded private key", "path", filePath)
+	return &PrivateKey{signer: signer}, nil
+}
+
+// LoadPrivateKeyFromPEMFile loads an existing private key from a PEM-encoded file.
+func LoadPrivateKeyFromPEMFile(filePath string) (*PrivateKey, error) {
+	data, err := os.ReadFile(filePath)
 	if err != nil {
-		// Use slog for error logging
-		slog.Error("Failed to parse DER-encoded private key", "path", filePath, "error", err)
-		return nil, fmt.Errorf("failed to parse DER-encoded private key from %s: %w", filePath, err)
+		slog.Error("Failed to read private key file", "path", filePath, "error", err)
+		return nil, fmt.Errorf("failed to read private key file %s: %w", filePath, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block from private key file %s", filePath)
+	}
+
+	signer, err := parsePrivateKeyPEMBlock(block)
+	if err != nil {
+		slog.Error("Failed to parse PEM-encoded private key", "path", filePath, "error", err)
+		return nil, fmt.Errorf("failed to parse PEM-encoded private key from %s: %w", filePath, err)
+	}
+
+	slog.Info("Loaded private key in PEM format", "path", filePath)
+	return &PrivateKey{signer: signer}, nil
+}
+
+// parsePrivateKeyPEMBlock parses the key material in block according to its
+// declared block type.
+func parsePrivateKeyPEMBlock(block *pem.Block) (crypto.Signer, error) {
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return parsePKCS8Signer(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q for private key", block.Type)
+	}
+}
+
+// parsePKCS8Signer parses PKCS#8 DER-encoded key material and asserts that
+// the result implements crypto.Signer.
+func parsePKCS8Signer(der []byte) (crypto.Signer, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("parsed key does not implement crypto.Signer")
 	}
-	// Use slog for info logging
-	slog.Info("Loaded private key in DER format", "path", filePath)
-	return &PrivateKey{key: privKey}, nil
+	return signer, nil
 }